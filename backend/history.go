@@ -0,0 +1,324 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// historyDepth bounds how many past generations are kept for undo/redo and
+// time-travel scrubbing.
+const historyDepth = 500
+
+// historyEntry is one recorded generation, snapshotted immediately after a
+// state-changing operation.
+type historyEntry struct {
+	Generation int64
+	Mode       gridMode
+	Epoch      int64  // identifies which grid configuration this generation belongs to
+	Snapshot   []byte // the exact JSON previously handed to the Publisher
+	Hash       string // order-independent hash of the live cell set
+}
+
+// History is a bounded ring buffer of past grid states with a cursor for
+// undo/redo, attached to the running simulation. All of its methods require
+// the caller to hold gridMux, since they read and write currentGrid /
+// currentSparseGrid.
+type History struct {
+	entries []historyEntry
+	cursor  int // index into entries of the current state; -1 if empty
+}
+
+var gridHistory = &History{cursor: -1}
+
+// recordLocked appends the current grid state to the history, discarding
+// any redo branch past the cursor and dropping the oldest entry once the
+// ring buffer exceeds historyDepth.
+func (h *History) recordLocked(snapshot []byte) {
+	entry := historyEntry{
+		Generation: currentGenerationLocked(),
+		Mode:       currentMode,
+		Epoch:      currentEpochLocked(),
+		Snapshot:   snapshot,
+		Hash:       hashLiveCellsLocked(),
+	}
+
+	h.entries = append(h.entries[:h.cursor+1], entry)
+	if len(h.entries) > historyDepth {
+		h.entries = append([]historyEntry{}, h.entries[len(h.entries)-historyDepth:]...)
+	}
+	h.cursor = len(h.entries) - 1
+
+	if period, firstSeenGen, ok := h.detectOscillationLocked(); ok {
+		log.Printf("period-%d oscillator detected at gen %d (first seen at gen %d)", period, entry.Generation, firstSeenGen)
+	}
+}
+
+// undoLocked moves the cursor back one entry and restores it, reporting
+// whether there was anywhere to undo to.
+func (h *History) undoLocked() bool {
+	if h.cursor <= 0 {
+		return false
+	}
+	h.cursor--
+	restoreEntryLocked(h.entries[h.cursor])
+	return true
+}
+
+// redoLocked moves the cursor forward one entry and restores it, reporting
+// whether there was a redo entry available.
+func (h *History) redoLocked() bool {
+	if h.cursor < 0 || h.cursor >= len(h.entries)-1 {
+		return false
+	}
+	h.cursor++
+	restoreEntryLocked(h.entries[h.cursor])
+	return true
+}
+
+// gotoGenerationLocked jumps directly to a recorded generation of the
+// currently active grid configuration, reporting whether that generation was
+// found in the history. Matching is restricted to the current mode and epoch
+// because bounded and infinite simulations number their generations
+// independently, and a resize/reset/reimport restarts the count at 0 without
+// erasing the pre-reset entries still sitting in the ring — without the
+// epoch check, a reused generation number could resolve to a stale snapshot
+// from before the reset instead of the one just created.
+func (h *History) gotoGenerationLocked(generation int64) bool {
+	epoch := currentEpochLocked()
+	for i, entry := range h.entries {
+		if entry.Mode == currentMode && entry.Epoch == epoch && entry.Generation == generation {
+			h.cursor = i
+			restoreEntryLocked(entry)
+			return true
+		}
+	}
+	return false
+}
+
+// detectOscillationLocked checks whether the current entry's hash matches
+// an earlier one still in the ring, which means the simulation has entered
+// a cycle. It returns the cycle's period and the generation it was first seen.
+// Matches are restricted to the current entry's epoch so that a reset or
+// resize reproducing an earlier hash by coincidence isn't reported as an
+// oscillation.
+func (h *History) detectOscillationLocked() (period, firstSeenGeneration int64, found bool) {
+	if h.cursor <= 0 {
+		return 0, 0, false
+	}
+	current := h.entries[h.cursor]
+	for i := h.cursor - 1; i >= 0; i-- {
+		if h.entries[i].Epoch != current.Epoch {
+			break
+		}
+		if h.entries[i].Generation >= current.Generation {
+			// Same-generation entries come from edits (toggles, rule changes),
+			// not from stepping, so a matching hash there is not a cycle.
+			continue
+		}
+		if h.entries[i].Mode == current.Mode && h.entries[i].Hash == current.Hash {
+			return current.Generation - h.entries[i].Generation, h.entries[i].Generation, true
+		}
+	}
+	return 0, 0, false
+}
+
+// currentGenerationLocked returns the generation counter of whichever grid
+// representation is currently active.
+func currentGenerationLocked() int64 {
+	if currentMode == modeInfinite {
+		return currentSparseGrid.Generation
+	}
+	return currentGrid.Generation
+}
+
+// currentEpochLocked returns the epoch of whichever grid representation is
+// currently active. See nextEpoch for what an epoch identifies.
+func currentEpochLocked() int64 {
+	if currentMode == modeInfinite {
+		return currentSparseGrid.epoch
+	}
+	return currentGrid.epoch
+}
+
+// hashLiveCellsLocked computes an order-independent hash of the live cell
+// set, used to detect stable and oscillating states.
+func hashLiveCellsLocked() string {
+	h := sha256.New()
+	if currentMode == modeInfinite {
+		cells := currentSparseGrid.Cells()
+		sort.Slice(cells, func(i, j int) bool {
+			if cells[i].Y != cells[j].Y {
+				return cells[i].Y < cells[j].Y
+			}
+			return cells[i].X < cells[j].X
+		})
+		for _, c := range cells {
+			fmt.Fprintf(h, "%d,%d;", c.X, c.Y)
+		}
+	} else {
+		for _, row := range currentGrid.Cells {
+			for _, alive := range row {
+				if alive {
+					h.Write([]byte{1})
+				} else {
+					h.Write([]byte{0})
+				}
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// restoreEntryLocked replaces the active grid representation with the state
+// captured in entry. The restored grid keeps entry's original epoch (JSON
+// round-tripping can't carry it, since it's unexported) rather than minting
+// a new one, so that a later gotoGenerationLocked/detectOscillationLocked
+// still recognizes this as the same configuration the entry came from.
+func restoreEntryLocked(entry historyEntry) {
+	currentMode = entry.Mode
+	if entry.Mode == modeInfinite {
+		var snap sparseSnapshot
+		if err := json.Unmarshal(entry.Snapshot, &snap); err != nil {
+			log.Println("Error restoring sparse grid from history:", err)
+			return
+		}
+		restored := NewSparseGrid()
+		restored.Generation = snap.Generation
+		restored.Rule = snap.Rule
+		restored.epoch = entry.Epoch
+		for _, c := range snap.Cells {
+			restored.Live[c] = struct{}{}
+		}
+		currentSparseGrid = restored
+		return
+	}
+
+	var restored Grid
+	if err := json.Unmarshal(entry.Snapshot, &restored); err != nil {
+		log.Println("Error restoring grid from history:", err)
+		return
+	}
+	restored.epoch = entry.Epoch
+	currentGrid = &restored
+}
+
+// historyEntrySummary is the wire format for a single entry in GET /api/history.
+type historyEntrySummary struct {
+	Generation int64  `json:"generation"`
+	Hash       string `json:"hash"`
+}
+
+// oscillatorInfo describes a detected cycle in the grid's recorded history.
+type oscillatorInfo struct {
+	Period              int64 `json:"period"`
+	FirstSeenGeneration int64 `json:"firstSeenGeneration"`
+}
+
+// historyResponse is the body of GET /api/history.
+type historyResponse struct {
+	Entries    []historyEntrySummary `json:"entries"`
+	Oscillator *oscillatorInfo       `json:"oscillator"`
+}
+
+// handleUndo steps the active grid back to the previous recorded generation.
+func handleUndo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gridMux.Lock()
+	defer gridMux.Unlock()
+
+	if !gridHistory.undoLocked() {
+		http.Error(w, "Nothing to undo", http.StatusBadRequest)
+		return
+	}
+	broadcastOnlyLocked(hub)
+	respondWithCurrentGridLocked(w, r)
+}
+
+// handleRedo steps the active grid forward to the next recorded generation.
+func handleRedo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gridMux.Lock()
+	defer gridMux.Unlock()
+
+	if !gridHistory.redoLocked() {
+		http.Error(w, "Nothing to redo", http.StatusBadRequest)
+		return
+	}
+	broadcastOnlyLocked(hub)
+	respondWithCurrentGridLocked(w, r)
+}
+
+// handleGoto jumps directly to the generation named by the "generation"
+// query parameter.
+func handleGoto(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	generation, err := strconv.ParseInt(r.URL.Query().Get("generation"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid or missing generation query parameter", http.StatusBadRequest)
+		return
+	}
+
+	gridMux.Lock()
+	defer gridMux.Unlock()
+
+	if !gridHistory.gotoGenerationLocked(generation) {
+		http.Error(w, "No recorded state for that generation", http.StatusNotFound)
+		return
+	}
+	broadcastOnlyLocked(hub)
+	respondWithCurrentGridLocked(w, r)
+}
+
+// handleHistory lists every recorded generation's number and hash, plus any
+// detected oscillation.
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gridMux.Lock()
+	defer gridMux.Unlock()
+
+	entries := make([]historyEntrySummary, len(gridHistory.entries))
+	for i, entry := range gridHistory.entries {
+		entries[i] = historyEntrySummary{Generation: entry.Generation, Hash: entry.Hash}
+	}
+
+	resp := historyResponse{Entries: entries}
+	if period, firstSeenGen, ok := gridHistory.detectOscillationLocked(); ok {
+		resp.Oscillator = &oscillatorInfo{Period: period, FirstSeenGeneration: firstSeenGen}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// respondWithCurrentGridLocked writes the active grid representation as the
+// HTTP response body. Callers must hold gridMux.
+func respondWithCurrentGridLocked(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if currentMode == modeInfinite {
+		json.NewEncoder(w).Encode(sparseSnapshotFromRequest(r))
+		return
+	}
+	json.NewEncoder(w).Encode(currentGrid)
+}
@@ -0,0 +1,196 @@
+package main
+
+import "testing"
+
+// setBoundedFixture points the package globals at a fresh 1x1 bounded grid
+// with the given generation/liveness, keeping whatever epoch currentGrid
+// already had (mirroring a plain "next generation" step).
+func setBoundedFixture(generation int64, alive bool) {
+	var epoch int64
+	if currentGrid != nil {
+		epoch = currentGrid.epoch
+	}
+	setBoundedFixtureWithEpoch(generation, alive, epoch)
+}
+
+// setBoundedFixtureWithEpoch is setBoundedFixture plus an explicit epoch, for
+// tests that simulate a resize/reset between recordings the way NewGrid and
+// ClearAllCells bump nextEpoch in the real code paths.
+func setBoundedFixtureWithEpoch(generation int64, alive bool, epoch int64) {
+	currentMode = modeBounded
+	currentGrid = &Grid{
+		Rows:       1,
+		Cols:       1,
+		Cells:      [][]CellState{{CellState(alive)}},
+		Rule:       defaultRule,
+		Generation: generation,
+		epoch:      epoch,
+	}
+}
+
+func recordFixture(h *History, generation int64, alive bool) {
+	setBoundedFixture(generation, alive)
+	snapshot, err := encodeCurrentGridLocked()
+	if err != nil {
+		panic(err)
+	}
+	h.recordLocked(snapshot)
+}
+
+func recordFixtureWithEpoch(h *History, generation int64, alive bool, epoch int64) {
+	setBoundedFixtureWithEpoch(generation, alive, epoch)
+	snapshot, err := encodeCurrentGridLocked()
+	if err != nil {
+		panic(err)
+	}
+	h.recordLocked(snapshot)
+}
+
+func TestHistoryUndoRedo(t *testing.T) {
+	origMode, origGrid, origSparse := currentMode, currentGrid, currentSparseGrid
+	defer func() { currentMode, currentGrid, currentSparseGrid = origMode, origGrid, origSparse }()
+
+	h := &History{cursor: -1}
+	recordFixture(h, 0, false)
+	recordFixture(h, 1, true)
+	recordFixture(h, 2, false)
+
+	if !h.undoLocked() {
+		t.Fatal("undoLocked() = false, want true (one entry back from the tail)")
+	}
+	if currentGrid.Generation != 1 || !bool(currentGrid.Cells[0][0]) {
+		t.Fatalf("after first undo, generation = %d alive = %v, want 1 true", currentGrid.Generation, currentGrid.Cells[0][0])
+	}
+
+	if !h.undoLocked() {
+		t.Fatal("undoLocked() = false, want true (back to the oldest entry)")
+	}
+	if currentGrid.Generation != 0 {
+		t.Fatalf("after second undo, generation = %d, want 0", currentGrid.Generation)
+	}
+
+	if h.undoLocked() {
+		t.Error("undoLocked() = true at the oldest entry, want false")
+	}
+
+	if !h.redoLocked() {
+		t.Fatal("redoLocked() = false, want true (forward to generation 1)")
+	}
+	if currentGrid.Generation != 1 {
+		t.Fatalf("after first redo, generation = %d, want 1", currentGrid.Generation)
+	}
+
+	if !h.redoLocked() {
+		t.Fatal("redoLocked() = false, want true (forward to generation 2)")
+	}
+	if currentGrid.Generation != 2 {
+		t.Fatalf("after second redo, generation = %d, want 2", currentGrid.Generation)
+	}
+
+	if h.redoLocked() {
+		t.Error("redoLocked() = true at the newest entry, want false")
+	}
+}
+
+func TestHistoryRecordAfterUndoTruncatesRedoBranch(t *testing.T) {
+	origMode, origGrid, origSparse := currentMode, currentGrid, currentSparseGrid
+	defer func() { currentMode, currentGrid, currentSparseGrid = origMode, origGrid, origSparse }()
+
+	h := &History{cursor: -1}
+	recordFixture(h, 0, false)
+	recordFixture(h, 1, true)
+	recordFixture(h, 2, false)
+
+	if !h.undoLocked() {
+		t.Fatal("undoLocked() = false, want true")
+	}
+	if currentGrid.Generation != 1 {
+		t.Fatalf("after undo, generation = %d, want 1", currentGrid.Generation)
+	}
+
+	// Recording a fresh state from here should discard the old generation-2
+	// entry rather than leaving it reachable via redo.
+	recordFixture(h, 5, true)
+
+	if len(h.entries) != 3 {
+		t.Fatalf("len(entries) = %d, want 3 (old redo branch must be dropped)", len(h.entries))
+	}
+	if h.entries[2].Generation != 5 {
+		t.Fatalf("entries[2].Generation = %d, want 5", h.entries[2].Generation)
+	}
+	if h.redoLocked() {
+		t.Error("redoLocked() = true after a new record replaced the redo branch, want false")
+	}
+}
+
+func TestHistoryGotoGeneration(t *testing.T) {
+	origMode, origGrid, origSparse := currentMode, currentGrid, currentSparseGrid
+	defer func() { currentMode, currentGrid, currentSparseGrid = origMode, origGrid, origSparse }()
+
+	h := &History{cursor: -1}
+	recordFixture(h, 0, false)
+	recordFixture(h, 1, true)
+	recordFixture(h, 2, false)
+
+	if !h.gotoGenerationLocked(1) {
+		t.Fatal("gotoGenerationLocked(1) = false, want true")
+	}
+	if currentGrid.Generation != 1 || !bool(currentGrid.Cells[0][0]) {
+		t.Fatalf("after goto(1), generation = %d alive = %v, want 1 true", currentGrid.Generation, currentGrid.Cells[0][0])
+	}
+
+	if h.gotoGenerationLocked(99) {
+		t.Error("gotoGenerationLocked(99) = true for an unrecorded generation, want false")
+	}
+}
+
+// TestHistoryGotoGenerationAcrossEpochs reproduces two "epochs" (as created
+// by a resize/reset/reimport) that each visit generation 0 and 1 with
+// different live state. gotoGenerationLocked must resolve to the current
+// epoch's entry, not the oldest entry with a matching generation number.
+func TestHistoryGotoGenerationAcrossEpochs(t *testing.T) {
+	origMode, origGrid, origSparse := currentMode, currentGrid, currentSparseGrid
+	defer func() { currentMode, currentGrid, currentSparseGrid = origMode, origGrid, origSparse }()
+
+	h := &History{cursor: -1}
+	// Epoch 1: generations 0 and 1, both dead.
+	recordFixtureWithEpoch(h, 0, false, 1)
+	recordFixtureWithEpoch(h, 1, false, 1)
+	// Epoch 2 (simulating a resize/reset): generations 0 and 1, both alive.
+	recordFixtureWithEpoch(h, 0, true, 2)
+	recordFixtureWithEpoch(h, 1, true, 2)
+
+	if !h.gotoGenerationLocked(1) {
+		t.Fatal("gotoGenerationLocked(1) = false, want true")
+	}
+	if currentGrid.Generation != 1 || !bool(currentGrid.Cells[0][0]) {
+		t.Fatalf("goto(1) restored generation=%d alive=%v, want the current epoch's generation 1 (alive=true), not the stale epoch 1's dead state",
+			currentGrid.Generation, currentGrid.Cells[0][0])
+	}
+
+	if !h.gotoGenerationLocked(0) {
+		t.Fatal("gotoGenerationLocked(0) = false, want true")
+	}
+	if currentGrid.Generation != 0 || !bool(currentGrid.Cells[0][0]) {
+		t.Fatalf("goto(0) restored generation=%d alive=%v, want the current epoch's generation 0 (alive=true), not the stale epoch 1's dead state",
+			currentGrid.Generation, currentGrid.Cells[0][0])
+	}
+}
+
+// TestHistoryDetectOscillationIgnoresStaleEpoch ensures a reset/resize that
+// happens to reproduce an earlier hash isn't reported as a real oscillation.
+func TestHistoryDetectOscillationIgnoresStaleEpoch(t *testing.T) {
+	origMode, origGrid, origSparse := currentMode, currentGrid, currentSparseGrid
+	defer func() { currentMode, currentGrid, currentSparseGrid = origMode, origGrid, origSparse }()
+
+	h := &History{cursor: -1}
+	// Epoch 1 settles into a dead, empty state at generation 1.
+	recordFixtureWithEpoch(h, 0, true, 1)
+	recordFixtureWithEpoch(h, 1, false, 1)
+	// Epoch 2 (post-reset) starts right back at the same dead, empty hash.
+	recordFixtureWithEpoch(h, 0, false, 2)
+
+	if _, _, found := h.detectOscillationLocked(); found {
+		t.Error("detectOscillationLocked() found a cycle spanning a reset into a new epoch, want none")
+	}
+}
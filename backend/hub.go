@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// clientSendBuffer is the depth of each client's outbound queue. Snapshots
+// are only a few hundred bytes, so a deep buffer absorbs bursts (e.g. a fast
+// tick rate) without the Publisher needing to drop messages in practice.
+const clientSendBuffer = 1024
+
+// defaultTickInterval is how often the simulation advances while running,
+// absent a client-supplied setSpeed command.
+const defaultTickInterval = 200 * time.Millisecond
+
+// Client is a single WebSocket connection subscribed to the Hub's Publisher.
+type Client struct {
+	conn *websocket.Conn
+	send chan []byte
+	done chan struct{}
+}
+
+// clientCommand is a client->server message received over the WebSocket,
+// letting an interactive UI drive the simulation without REST round-trips.
+type clientCommand struct {
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Hub drives the server-side simulation ticker and fans grid updates out to
+// every connected client via a Publisher, so a generation is encoded to JSON
+// exactly once regardless of how many viewers are watching.
+type Hub struct {
+	publisher   *Publisher
+	setInterval chan time.Duration
+	start       chan struct{}
+	stop        chan struct{}
+}
+
+// NewHub creates a Hub with no subscribers and the simulation stopped.
+func NewHub() *Hub {
+	return &Hub{
+		publisher:   NewPublisher(),
+		setInterval: make(chan time.Duration),
+		start:       make(chan struct{}),
+		stop:        make(chan struct{}),
+	}
+}
+
+// Register subscribes a client to receive broadcast messages until its done
+// channel is closed.
+func (h *Hub) Register(c *Client) {
+	h.publisher.SubscribeJSON(c.send, c.done)
+}
+
+// Unregister stops delivery to a client.
+func (h *Hub) Unregister(c *Client) {
+	close(c.done)
+}
+
+// Broadcast hands an already-encoded message to the Publisher for fan-out.
+func (h *Hub) Broadcast(message []byte) {
+	h.publisher.Publish(message)
+}
+
+// Start turns on the simulation ticker.
+func (h *Hub) Start() {
+	h.start <- struct{}{}
+}
+
+// Stop turns off the simulation ticker.
+func (h *Hub) Stop() {
+	h.stop <- struct{}{}
+}
+
+// SetSpeed changes the interval between automatic generations.
+func (h *Hub) SetSpeed(interval time.Duration) {
+	h.setInterval <- interval
+}
+
+// Run drives the simulation ticker until the program exits. It must be
+// started as its own goroutine.
+func (h *Hub) Run() {
+	ticker := time.NewTicker(defaultTickInterval)
+	defer ticker.Stop()
+
+	running := false
+
+	for {
+		select {
+		case interval := <-h.setInterval:
+			ticker.Reset(interval)
+
+		case <-h.start:
+			running = true
+
+		case <-h.stop:
+			running = false
+
+		case <-ticker.C:
+			if running {
+				advanceSimulationAndBroadcast()
+			}
+		}
+	}
+}
+
+// writePump relays messages queued for the client to its WebSocket
+// connection. It owns the connection's writes and exits once the client is
+// unregistered.
+func (c *Client) writePump() {
+	defer c.conn.Close()
+	for {
+		select {
+		case message := <-c.send:
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// readPump reads client->server commands until the connection closes.
+func (c *Client) readPump(h *Hub) {
+	defer h.Unregister(c)
+	defer c.conn.Close()
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var cmd clientCommand
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			log.Println("Ignoring malformed WebSocket command:", err)
+			continue
+		}
+		handleClientCommand(h, cmd)
+	}
+}
+
+// handleClientCommand applies a single client command and, if it changed
+// simulation state, broadcasts the resulting grid to every client.
+func handleClientCommand(h *Hub, cmd clientCommand) {
+	switch cmd.Type {
+	case "toggle":
+		var payload struct {
+			Row   int64 `json:"row"`
+			Col   int64 `json:"col"`
+			State bool  `json:"state"`
+		}
+		if err := json.Unmarshal(cmd.Payload, &payload); err != nil {
+			log.Println("Ignoring malformed toggle command:", err)
+			return
+		}
+		gridMux.Lock()
+		if currentMode == modeInfinite {
+			currentSparseGrid.SetCell(payload.Col, payload.Row, payload.State)
+		} else {
+			currentGrid.SetCell(int(payload.Row), int(payload.Col), CellState(payload.State))
+		}
+		recordAndBroadcastLocked(h)
+		gridMux.Unlock()
+
+	case "next":
+		gridMux.Lock()
+		advanceGridLocked()
+		recordAndBroadcastLocked(h)
+		gridMux.Unlock()
+
+	case "reset":
+		gridMux.Lock()
+		if currentMode == modeInfinite {
+			currentSparseGrid.ClearAllCells()
+		} else {
+			currentGrid.ClearAllCells()
+		}
+		recordAndBroadcastLocked(h)
+		gridMux.Unlock()
+
+	case "start":
+		h.Start()
+
+	case "stop":
+		h.Stop()
+
+	case "setSpeed":
+		var payload struct {
+			IntervalMs int64 `json:"intervalMs"`
+		}
+		if err := json.Unmarshal(cmd.Payload, &payload); err != nil || payload.IntervalMs <= 0 {
+			log.Println("Ignoring malformed setSpeed command")
+			return
+		}
+		h.SetSpeed(time.Duration(payload.IntervalMs) * time.Millisecond)
+
+	default:
+		log.Println("Ignoring unknown WebSocket command type:", cmd.Type)
+	}
+}
+
+// advanceGridLocked steps the active grid representation by one generation.
+// Callers must hold gridMux.
+func advanceGridLocked() {
+	if currentMode == modeInfinite {
+		currentSparseGrid.NextGeneration()
+	} else {
+		currentGrid.NextGeneration()
+	}
+}
+
+// advanceSimulationAndBroadcast is called from the hub's own goroutine on
+// each simulation tick while running.
+func advanceSimulationAndBroadcast() {
+	gridMux.Lock()
+	defer gridMux.Unlock()
+	advanceGridLocked()
+	recordAndBroadcastLocked(hub)
+}
+
+// recordAndBroadcastLocked encodes the active grid representation once,
+// appends it to the generation history, and hands the bytes to the hub's
+// Publisher for fan-out. Callers must hold gridMux.
+func recordAndBroadcastLocked(h *Hub) {
+	message, err := encodeCurrentGridLocked()
+	if err != nil {
+		log.Println("Error encoding grid for broadcast:", err)
+		return
+	}
+	gridHistory.recordLocked(message)
+	h.Broadcast(message)
+}
+
+// broadcastOnlyLocked re-sends the active grid representation without
+// touching the history ring, for use after undo/redo/goto where the state
+// already has a history entry. Callers must hold gridMux.
+func broadcastOnlyLocked(h *Hub) {
+	message, err := encodeCurrentGridLocked()
+	if err != nil {
+		log.Println("Error encoding grid for broadcast:", err)
+		return
+	}
+	h.Broadcast(message)
+}
+
+// encodeCurrentGridLocked marshals the active grid representation to JSON.
+// Callers must hold gridMux.
+func encodeCurrentGridLocked() ([]byte, error) {
+	if currentMode == modeInfinite {
+		return json.Marshal(sparseSnapshotForBroadcast())
+	}
+	return json.Marshal(currentGrid)
+}
+
+// sparseSnapshotForBroadcast builds a full-world sparse snapshot for the
+// broadcast path, where there is no per-viewer viewport to honor.
+func sparseSnapshotForBroadcast() sparseSnapshot {
+	return sparseSnapshot{
+		Mode:       modeInfinite,
+		Generation: currentSparseGrid.Generation,
+		Rule:       currentSparseGrid.Rule,
+		Cells:      currentSparseGrid.Cells(),
+	}
+}
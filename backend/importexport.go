@@ -0,0 +1,337 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ParseRLE decodes a run-length-encoded pattern in the format produced by
+// Golly and similar tools: a header line ("x = W, y = H, rule = B3/S23")
+// followed by rows of runs using 'b' (dead), 'o' (alive) and '$' (end of
+// row), terminated by '!'. Lines starting with '#' are treated as comments.
+func ParseRLE(data string) (cells []Cell, width, height int64, rule string, err error) {
+	var headerFound bool
+	var body strings.Builder
+
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !headerFound && strings.HasPrefix(line, "x") {
+			width, height, rule, err = parseRLEHeader(line)
+			if err != nil {
+				return nil, 0, 0, "", err
+			}
+			headerFound = true
+			continue
+		}
+		body.WriteString(line)
+	}
+	if !headerFound {
+		return nil, 0, 0, "", fmt.Errorf("rle: missing header line")
+	}
+
+	var x, y, run int64
+	for _, ch := range body.String() {
+		switch {
+		case ch >= '0' && ch <= '9':
+			run = run*10 + int64(ch-'0')
+		case ch == 'b':
+			x += runOrOne(run)
+			run = 0
+		case ch == 'o':
+			for i := int64(0); i < runOrOne(run); i++ {
+				cells = append(cells, Cell{X: x, Y: y})
+				x++
+			}
+			run = 0
+		case ch == '$':
+			y += runOrOne(run)
+			x = 0
+			run = 0
+		case ch == '!':
+			return cells, width, height, rule, nil
+		default:
+			return nil, 0, 0, "", fmt.Errorf("rle: unexpected character %q", ch)
+		}
+	}
+	return cells, width, height, rule, nil
+}
+
+func runOrOne(run int64) int64 {
+	if run == 0 {
+		return 1
+	}
+	return run
+}
+
+// parseRLEHeader parses "x = W, y = H, rule = B3/S23" (rule optional).
+func parseRLEHeader(line string) (width, height int64, rule string, err error) {
+	for _, field := range strings.Split(line, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		switch key {
+		case "x":
+			width, err = strconv.ParseInt(value, 10, 64)
+		case "y":
+			height, err = strconv.ParseInt(value, 10, 64)
+		case "rule":
+			rule = value
+		}
+		if err != nil {
+			return 0, 0, "", fmt.Errorf("rle: invalid header field %q: %w", field, err)
+		}
+	}
+	return width, height, rule, nil
+}
+
+// ExportRLE encodes the live cells within a width x height box, relative to
+// its top-left corner, as compact RLE.
+func ExportRLE(cells []Cell, width, height int64, rule string) string {
+	alive := make(map[Cell]struct{}, len(cells))
+	for _, c := range cells {
+		alive[c] = struct{}{}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "x = %d, y = %d, rule = %s\n", width, height, rule)
+
+	for y := int64(0); y < height; y++ {
+		type run struct {
+			ch  byte
+			len int64
+		}
+		var runs []run
+		for x := int64(0); x < width; x++ {
+			ch := byte('b')
+			if _, ok := alive[Cell{X: x, Y: y}]; ok {
+				ch = 'o'
+			}
+			if n := len(runs); n > 0 && runs[n-1].ch == ch {
+				runs[n-1].len++
+			} else {
+				runs = append(runs, run{ch: ch, len: 1})
+			}
+		}
+		// Trailing dead cells are implied by the end of the row; omit them.
+		if n := len(runs); n > 0 && runs[n-1].ch == 'b' {
+			runs = runs[:n-1]
+		}
+		for _, rn := range runs {
+			if rn.len > 1 {
+				fmt.Fprintf(&b, "%d", rn.len)
+			}
+			b.WriteByte(rn.ch)
+		}
+		if y < height-1 {
+			b.WriteByte('$')
+		}
+	}
+	b.WriteByte('!')
+	return b.String()
+}
+
+// ParseLife106 decodes the Life 1.06 format: a "#Life 1.06" header followed
+// by one "x y" integer pair per live cell.
+func ParseLife106(data string) ([]Cell, error) {
+	var cells []Cell
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("life106: malformed line %q", line)
+		}
+		x, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("life106: invalid x in %q: %w", line, err)
+		}
+		y, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("life106: invalid y in %q: %w", line, err)
+		}
+		cells = append(cells, Cell{X: x, Y: y})
+	}
+	return cells, nil
+}
+
+// ExportLife106 encodes live cells as Life 1.06, using their absolute
+// coordinates.
+func ExportLife106(cells []Cell) string {
+	var b strings.Builder
+	b.WriteString("#Life 1.06\n")
+	for _, c := range cells {
+		fmt.Fprintf(&b, "%d %d\n", c.X, c.Y)
+	}
+	return b.String()
+}
+
+// importRequest is the body of POST /api/grid/import.
+type importRequest struct {
+	Format string `json:"format"` // "rle" or "life106"
+	Data   string `json:"data"`
+	Row    int64  `json:"row"`
+	Col    int64  `json:"col"`
+}
+
+// handleGridImport replaces the current grid's live cells with a pattern
+// decoded from RLE or Life 1.06 text, offset by the given row/col.
+func handleGridImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req importRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var cells []Cell
+	var ruleString string
+	var err error
+	switch req.Format {
+	case "rle":
+		cells, _, _, ruleString, err = ParseRLE(req.Data)
+	case "life106":
+		cells, err = ParseLife106(req.Data)
+	default:
+		http.Error(w, "Unknown format: "+req.Format, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var rule Rule
+	var hasRule bool
+	if ruleString != "" {
+		rule, err = ParseRule(ruleString)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		hasRule = true
+	}
+
+	gridMux.Lock()
+	defer gridMux.Unlock()
+
+	if currentMode == modeInfinite {
+		currentSparseGrid.ClearAllCells()
+		if hasRule {
+			currentSparseGrid.Rule = rule
+		}
+	} else {
+		currentGrid.ClearAllCells()
+		if hasRule {
+			currentGrid.Rule = rule
+		}
+	}
+	for _, c := range cells {
+		x, y := c.X+req.Col, c.Y+req.Row
+		if currentMode == modeInfinite {
+			currentSparseGrid.SetCell(x, y, true)
+		} else {
+			currentGrid.SetCell(int(y), int(x), true)
+		}
+	}
+	recordAndBroadcastLocked(hub)
+
+	w.Header().Set("Content-Type", "application/json")
+	if currentMode == modeInfinite {
+		json.NewEncoder(w).Encode(sparseSnapshotFromRequest(r))
+		return
+	}
+	json.NewEncoder(w).Encode(currentGrid)
+}
+
+// handleGridExport serializes the current grid's live cells as RLE or
+// Life 1.06 text, chosen via the "format" query parameter (default "rle").
+func handleGridExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "rle"
+	}
+
+	gridMux.Lock()
+	cells, width, height := liveCellsForExportLocked()
+	rule := activeRuleLocked()
+	gridMux.Unlock()
+
+	var out string
+	switch format {
+	case "rle":
+		out = ExportRLE(cells, width, height, rule.String())
+	case "life106":
+		out = ExportLife106(cells)
+	default:
+		http.Error(w, "Unknown format: "+format, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(out))
+}
+
+// liveCellsForExportLocked returns the live cells of the active grid plus
+// the dimensions of the box they are exported in. For the infinite grid the
+// cells are normalized to the top-left of their bounding box and that box's
+// dimensions are returned; for the bounded grid the cells keep their grid
+// coordinates and the grid's own Cols/Rows are returned. Callers must hold
+// gridMux.
+func liveCellsForExportLocked() (cells []Cell, width, height int64) {
+	if currentMode == modeInfinite {
+		live := currentSparseGrid.Cells()
+		if len(live) == 0 {
+			return nil, 0, 0
+		}
+		minX, minY := live[0].X, live[0].Y
+		maxX, maxY := live[0].X, live[0].Y
+		for _, c := range live {
+			if c.X < minX {
+				minX = c.X
+			}
+			if c.Y < minY {
+				minY = c.Y
+			}
+			if c.X > maxX {
+				maxX = c.X
+			}
+			if c.Y > maxY {
+				maxY = c.Y
+			}
+		}
+		normalized := make([]Cell, len(live))
+		for i, c := range live {
+			normalized[i] = Cell{X: c.X - minX, Y: c.Y - minY}
+		}
+		return normalized, maxX - minX + 1, maxY - minY + 1
+	}
+
+	for row := 0; row < currentGrid.Rows; row++ {
+		for col := 0; col < currentGrid.Cols; col++ {
+			if currentGrid.Cells[row][col] {
+				cells = append(cells, Cell{X: int64(col), Y: int64(row)})
+			}
+		}
+	}
+	return cells, int64(currentGrid.Cols), int64(currentGrid.Rows)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func sortedCells(cells []Cell) []Cell {
+	out := append([]Cell(nil), cells...)
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Y != out[j].Y {
+			return out[i].Y < out[j].Y
+		}
+		return out[i].X < out[j].X
+	})
+	return out
+}
+
+func TestParseExportRLERoundTrip(t *testing.T) {
+	// A glider in its 3x3 bounding box; its top-left cell is dead, so the
+	// first row starts with a dead run.
+	cells := []Cell{{X: 1, Y: 0}, {X: 2, Y: 1}, {X: 0, Y: 2}, {X: 1, Y: 2}, {X: 2, Y: 2}}
+	const width, height = 3, 3
+	const rule = "B3/S23"
+
+	encoded := ExportRLE(cells, width, height, rule)
+
+	gotCells, gotWidth, gotHeight, gotRule, err := ParseRLE(encoded)
+	if err != nil {
+		t.Fatalf("ParseRLE(%q) returned error: %v", encoded, err)
+	}
+	if gotWidth != width || gotHeight != height {
+		t.Errorf("dimensions = %dx%d, want %dx%d", gotWidth, gotHeight, width, height)
+	}
+	if gotRule != rule {
+		t.Errorf("rule = %q, want %q", gotRule, rule)
+	}
+	if !reflect.DeepEqual(sortedCells(gotCells), sortedCells(cells)) {
+		t.Errorf("cells = %v, want %v", sortedCells(gotCells), sortedCells(cells))
+	}
+}
+
+func TestExportRLEAllDeadRow(t *testing.T) {
+	// Only the first row has a live cell; the second row is entirely dead.
+	cells := []Cell{{X: 0, Y: 0}}
+	out := ExportRLE(cells, 2, 2, "B3/S23")
+
+	gotCells, gotWidth, gotHeight, _, err := ParseRLE(out)
+	if err != nil {
+		t.Fatalf("ParseRLE(%q) returned error: %v", out, err)
+	}
+	if gotWidth != 2 || gotHeight != 2 {
+		t.Errorf("dimensions = %dx%d, want 2x2", gotWidth, gotHeight)
+	}
+	if !reflect.DeepEqual(sortedCells(gotCells), sortedCells(cells)) {
+		t.Errorf("cells = %v, want %v", gotCells, cells)
+	}
+}
+
+func TestExportRLETrailingDeadCellsOmitted(t *testing.T) {
+	// A single live cell in the middle of an otherwise dead row: the leading
+	// dead run must still be encoded, but the trailing dead run is implied.
+	cells := []Cell{{X: 1, Y: 0}}
+	out := ExportRLE(cells, 5, 1, "B3/S23")
+
+	body := out[strings.Index(out, "\n")+1:]
+	if body != "bo!" {
+		t.Errorf("body = %q, want %q (trailing dead run should be omitted)", body, "bo!")
+	}
+
+	gotCells, _, _, _, err := ParseRLE(out)
+	if err != nil {
+		t.Fatalf("ParseRLE(%q) returned error: %v", out, err)
+	}
+	if !reflect.DeepEqual(sortedCells(gotCells), sortedCells(cells)) {
+		t.Errorf("cells = %v, want %v", gotCells, cells)
+	}
+}
+
+func TestParseLife106RoundTrip(t *testing.T) {
+	cells := []Cell{{X: -1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}}
+	encoded := ExportLife106(cells)
+
+	got, err := ParseLife106(encoded)
+	if err != nil {
+		t.Fatalf("ParseLife106(%q) returned error: %v", encoded, err)
+	}
+	if !reflect.DeepEqual(sortedCells(got), sortedCells(cells)) {
+		t.Errorf("cells = %v, want %v", got, cells)
+	}
+}
+
+func TestParseRLERejectsMissingHeader(t *testing.T) {
+	if _, _, _, _, err := ParseRLE("bo$ob!"); err == nil {
+		t.Error("expected error for RLE data with no header line, got nil")
+	}
+}
@@ -5,34 +5,48 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"sync"
-	"time"
 
 	"github.com/gorilla/websocket" // Using gorilla/websocket for easy WebSocket handling
 	"github.com/rs/cors"          // Using rs/cors for handling CORS
 )
 
+// gridMode selects which representation backs the current simulation.
+type gridMode string
+
+const (
+	modeBounded  gridMode = "bounded"
+	modeInfinite gridMode = "infinite"
+)
+
 // CellState represents the state of a cell
 type CellState bool
 
 // Grid represents the Game of Life grid
 type Grid struct {
-	Rows int
-	Cols int
-	Cells [][]CellState
-	mu sync.Mutex // Mutex to protect concurrent access to the grid
+	Rows       int
+	Cols       int
+	Cells      [][]CellState
+	Rule       Rule
+	Generation int64
+	epoch      int64      // bumped whenever the grid is replaced or cleared; see nextEpoch
+	mu         sync.Mutex // Mutex to protect concurrent access to the grid
 }
 
-// NewGrid creates a new empty grid
+// NewGrid creates a new empty grid using Conway's B3/S23 rule.
 func NewGrid(rows, cols int) *Grid {
 	cells := make([][]CellState, rows)
 	for i := range cells {
 		cells[i] = make([]CellState, cols)
 	}
+	nextEpoch++
 	return &Grid{
 		Rows:  rows,
 		Cols:  cols,
 		Cells: cells,
+		Rule:  defaultRule,
+		epoch: nextEpoch,
 	}
 }
 
@@ -55,6 +69,9 @@ func (g *Grid) ClearAllCells() {
 			g.Cells[r][c] = false
 		}
 	}
+	g.Generation = 0
+	nextEpoch++
+	g.epoch = nextEpoch
 }
 
 // NextGeneration calculates the next generation of the grid
@@ -70,22 +87,15 @@ func (g *Grid) NextGeneration() {
 	for r := 0; r < g.Rows; r++ {
 		for c := 0; c < g.Cols; c++ {
 			liveNeighbors := g.countLiveNeighbors(r, c)
+			mask := g.Rule.Birth
 			if g.Cells[r][c] { // Currently alive
-				if liveNeighbors < 2 || liveNeighbors > 3 {
-					newCells[r][c] = false // Underpopulation or Overpopulation
-				} else {
-					newCells[r][c] = true // Survives
-				}
-			} else { // Currently dead
-				if liveNeighbors == 3 {
-					newCells[r][c] = true // Reproduction
-				} else {
-					newCells[r][c] = false // Remains dead
-				}
+				mask = g.Rule.Survive
 			}
+			newCells[r][c] = CellState(mask>>uint(liveNeighbors)&1 == 1)
 		}
 	}
 	g.Cells = newCells
+	g.Generation++
 }
 
 // countLiveNeighbors counts the live neighbors for a given cell
@@ -116,14 +126,30 @@ var upgrader = websocket.Upgrader{
 }
 
 var currentGrid *Grid
-var gridMux sync.Mutex // Mutex for currentGrid pointer
+var currentSparseGrid *SparseGrid
+var currentMode gridMode
+var gridMux sync.Mutex // Mutex for currentGrid/currentSparseGrid pointers
+
+// nextEpoch is a monotonically increasing counter bumped every time the
+// active grid is replaced or cleared (resize, mode switch, reset, import).
+// Generation counters restart at 0 on each of those events, so history
+// entries tag themselves with the epoch in effect when they were recorded
+// to tell apart same-numbered generations from different configurations.
+// Callers must hold gridMux when incrementing it.
+var nextEpoch int64
+
+var hub = NewHub()
 
 func main() {
 	// Initialize default grid
 	gridMux.Lock()
 	currentGrid = NewGrid(5, 5) // Default 5x5 grid
+	currentMode = modeBounded
+	recordAndBroadcastLocked(hub)
 	gridMux.Unlock()
 
+	go hub.Run()
+
 	// Handle CORS
 	c := cors.New(cors.Options{
 		AllowedOrigins: []string{"http://localhost:3000"}, // Adjust this to your frontend's URL
@@ -137,6 +163,15 @@ func main() {
 	http.Handle("/api/grid/reset", c.Handler(http.HandlerFunc(handleResetGrid)))
 	http.Handle("/api/cell", c.Handler(http.HandlerFunc(handleCellToggle)))
 	http.Handle("/api/next", c.Handler(http.HandlerFunc(handleNextGeneration)))
+	http.Handle("/api/patterns", c.Handler(http.HandlerFunc(handlePatternsList)))
+	http.Handle("/api/patterns/", c.Handler(http.HandlerFunc(handleStampPattern)))
+	http.Handle("/api/grid/import", c.Handler(http.HandlerFunc(handleGridImport)))
+	http.Handle("/api/grid/export", c.Handler(http.HandlerFunc(handleGridExport)))
+	http.Handle("/api/grid/rule", c.Handler(http.HandlerFunc(handleSetRule)))
+	http.Handle("/api/undo", c.Handler(http.HandlerFunc(handleUndo)))
+	http.Handle("/api/redo", c.Handler(http.HandlerFunc(handleRedo)))
+	http.Handle("/api/history", c.Handler(http.HandlerFunc(handleHistory)))
+	http.Handle("/api/goto", c.Handler(http.HandlerFunc(handleGoto)))
 
 	port := ":8080"
 	fmt.Printf("Go server listening on port %s\n", port)
@@ -149,21 +184,32 @@ func handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		log.Println("Error upgrading to WebSocket:", err)
 		return
 	}
-	defer conn.Close()
+
+	client := &Client{conn: conn, send: make(chan []byte, clientSendBuffer), done: make(chan struct{})}
+	hub.Register(client)
 
 	// Send initial grid state
 	gridMux.Lock()
-	initialGridBytes, _ := json.Marshal(currentGrid)
+	var initialGridBytes []byte
+	if currentMode == modeInfinite {
+		initialGridBytes, _ = json.Marshal(sparseSnapshotFromRequest(r))
+	} else {
+		initialGridBytes, _ = json.Marshal(currentGrid)
+	}
 	gridMux.Unlock()
-	conn.WriteMessage(websocket.TextMessage, initialGridBytes)
+	client.send <- initialGridBytes
 
-	// Keep connection open to send updates
-	for {
-		// This loop can be used to receive messages from the client if needed,
-		// but for this Game of Life, updates are primarily server-to-client.
-		// For now, just keep the connection alive.
-		time.Sleep(5 * time.Second) // Keep alive, adjust as needed
-	}
+	go client.writePump()
+	client.readPump(hub) // Blocks until the connection closes, then unregisters.
+}
+
+// sparseSnapshot is the wire format for the infinite grid: only the live
+// cells are sent, optionally restricted to a requested viewport.
+type sparseSnapshot struct {
+	Mode       gridMode `json:"mode"`
+	Generation int64    `json:"generation"`
+	Rule       Rule     `json:"rule"`
+	Cells      []Cell   `json:"cells"`
 }
 
 func handleGrid(w http.ResponseWriter, r *http.Request) {
@@ -173,23 +219,39 @@ func handleGrid(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case "GET":
 		w.Header().Set("Content-Type", "application/json")
+		if currentMode == modeInfinite {
+			json.NewEncoder(w).Encode(sparseSnapshotFromRequest(r))
+			return
+		}
 		json.NewEncoder(w).Encode(currentGrid)
 	case "POST":
 		var requestBody struct {
-			Rows int `json:"rows"`
-			Cols int `json:"cols"`
+			Rows int    `json:"rows"`
+			Cols int    `json:"cols"`
+			Mode string `json:"mode"` // "bounded" (default) or "infinite"
 		}
 		if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
+		if gridMode(requestBody.Mode) == modeInfinite {
+			currentMode = modeInfinite
+			currentSparseGrid = NewSparseGrid()
+			recordAndBroadcastLocked(hub)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(sparseSnapshotFromRequest(r))
+			return
+		}
+
 		if requestBody.Rows <= 0 || requestBody.Cols <= 0 || requestBody.Rows > 20 || requestBody.Cols > 20 {
 			http.Error(w, "Invalid grid dimensions. Max 20x20.", http.StatusBadRequest)
 			return
 		}
 
+		currentMode = modeBounded
 		currentGrid = NewGrid(requestBody.Rows, requestBody.Cols)
+		recordAndBroadcastLocked(hub)
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(currentGrid)
 	default:
@@ -197,6 +259,31 @@ func handleGrid(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// sparseSnapshotFromRequest builds a sparseSnapshot for the current sparse
+// grid, restricted to the viewport named by the minX/minY/maxX/maxY query
+// parameters when all four are present.
+func sparseSnapshotFromRequest(r *http.Request) sparseSnapshot {
+	q := r.URL.Query()
+	minX, errMinX := strconv.ParseInt(q.Get("minX"), 10, 64)
+	minY, errMinY := strconv.ParseInt(q.Get("minY"), 10, 64)
+	maxX, errMaxX := strconv.ParseInt(q.Get("maxX"), 10, 64)
+	maxY, errMaxY := strconv.ParseInt(q.Get("maxY"), 10, 64)
+
+	var cells []Cell
+	if errMinX == nil && errMinY == nil && errMaxX == nil && errMaxY == nil {
+		cells = currentSparseGrid.CellsIn(minX, minY, maxX, maxY)
+	} else {
+		cells = currentSparseGrid.Cells()
+	}
+
+	return sparseSnapshot{
+		Mode:       modeInfinite,
+		Generation: currentSparseGrid.Generation,
+		Rule:       currentSparseGrid.Rule,
+		Cells:      cells,
+	}
+}
+
 func handleResetGrid(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -204,8 +291,19 @@ func handleResetGrid(w http.ResponseWriter, r *http.Request) {
 	}
 	gridMux.Lock()
 	defer gridMux.Unlock()
-	currentGrid.ClearAllCells()
+
+	if currentMode == modeInfinite {
+		currentSparseGrid.ClearAllCells()
+	} else {
+		currentGrid.ClearAllCells()
+	}
+	recordAndBroadcastLocked(hub)
+
 	w.WriteHeader(http.StatusOK)
+	if currentMode == modeInfinite {
+		json.NewEncoder(w).Encode(sparseSnapshotFromRequest(r))
+		return
+	}
 	json.NewEncoder(w).Encode(currentGrid) // Send updated grid
 }
 
@@ -216,9 +314,9 @@ func handleCellToggle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var requestBody struct {
-		Row   int  `json:"row"`
-		Col   int  `json:"col"`
-		State bool `json:"state"` // true for active, false for inactive
+		Row   int64 `json:"row"`
+		Col   int64 `json:"col"`
+		State bool  `json:"state"` // true for active, false for inactive
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
@@ -229,8 +327,18 @@ func handleCellToggle(w http.ResponseWriter, r *http.Request) {
 	gridMux.Lock()
 	defer gridMux.Unlock()
 
-	currentGrid.SetCell(requestBody.Row, requestBody.Col, CellState(requestBody.State))
+	if currentMode == modeInfinite {
+		currentSparseGrid.SetCell(requestBody.Col, requestBody.Row, requestBody.State)
+	} else {
+		currentGrid.SetCell(int(requestBody.Row), int(requestBody.Col), CellState(requestBody.State))
+	}
+	recordAndBroadcastLocked(hub)
+
 	w.WriteHeader(http.StatusOK)
+	if currentMode == modeInfinite {
+		json.NewEncoder(w).Encode(sparseSnapshotFromRequest(r))
+		return
+	}
 	json.NewEncoder(w).Encode(currentGrid) // Send updated grid
 }
 
@@ -241,8 +349,15 @@ func handleNextGeneration(w http.ResponseWriter, r *http.Request) {
 	}
 	gridMux.Lock()
 	defer gridMux.Unlock()
-	currentGrid.NextGeneration()
+
+	advanceGridLocked()
+	recordAndBroadcastLocked(hub)
+
 	w.WriteHeader(http.StatusOK)
+	if currentMode == modeInfinite {
+		json.NewEncoder(w).Encode(sparseSnapshotFromRequest(r))
+		return
+	}
 	json.NewEncoder(w).Encode(currentGrid) // Send updated grid
 }
 
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Pattern is a named, reusable arrangement of live cells that can be
+// stamped into the current grid at an arbitrary offset.
+type Pattern struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Width       int64  `json:"width"`
+	Height      int64  `json:"height"`
+	Cells       []Cell `json:"-"` // offsets from the top-left, X=col, Y=row
+}
+
+// parseAsciiPattern turns a small ASCII-art block ('O'/'#' alive, anything
+// else dead) into the list of live-cell offsets it represents. It exists so
+// the catalog below can be written and reviewed as a picture.
+func parseAsciiPattern(art string) []Cell {
+	var cells []Cell
+	for y, line := range strings.Split(strings.Trim(art, "\n"), "\n") {
+		for x, ch := range line {
+			if ch == 'O' || ch == '#' {
+				cells = append(cells, Cell{X: int64(x), Y: int64(y)})
+			}
+		}
+	}
+	return cells
+}
+
+// newPattern builds a Pattern from ASCII art, deriving its bounding box.
+func newPattern(name, description, art string) Pattern {
+	cells := parseAsciiPattern(art)
+	var width, height int64
+	for _, c := range cells {
+		if c.X+1 > width {
+			width = c.X + 1
+		}
+		if c.Y+1 > height {
+			height = c.Y + 1
+		}
+	}
+	return Pattern{Name: name, Description: description, Width: width, Height: height, Cells: cells}
+}
+
+// patternCatalog is the curated set of patterns available via the API.
+var patternCatalog = buildPatternCatalog()
+
+func buildPatternCatalog() map[string]Pattern {
+	patterns := []Pattern{
+		newPattern("glider", "Smallest spaceship, moves diagonally every 4 generations.", ".#.\n..#\n###"),
+		newPattern("lwss", "Lightweight spaceship, moves horizontally.", ".#..#\n#....\n#...#\n####."),
+		newPattern("r-pentomino", "Methuselah that stabilizes after 1103 generations.", ".##\n##.\n.#."),
+		newPattern("gosper-glider-gun", "First known pattern to produce gliders indefinitely.",
+			strings.Join([]string{
+				"........................O...........",
+				"......................O.O...........",
+				"............OO......OO............OO",
+				"...........O...O....OO............OO",
+				"OO........O.....O...OO..............",
+				"OO........O...O.OO....O.O...........",
+				"..........O.....O.......O...........",
+				"...........O...O....................",
+				"............OO......................",
+			}, "\n")),
+		newPattern("pulsar", "Period-3 oscillator.", strings.Join([]string{
+			"..OOO...OOO..",
+			".............",
+			"O....O.O....O",
+			"O....O.O....O",
+			"O....O.O....O",
+			"..OOO...OOO..",
+			".............",
+			"..OOO...OOO..",
+			"O....O.O....O",
+			"O....O.O....O",
+			"O....O.O....O",
+			".............",
+			"..OOO...OOO..",
+		}, "\n")),
+	}
+
+	catalog := make(map[string]Pattern, len(patterns))
+	for _, p := range patterns {
+		catalog[p.Name] = p
+	}
+	return catalog
+}
+
+// handlePatternsList serves the catalog of available patterns.
+func handlePatternsList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	patterns := make([]Pattern, 0, len(patternCatalog))
+	for _, p := range patternCatalog {
+		patterns = append(patterns, p)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(patterns)
+}
+
+// handleStampPattern stamps a catalog pattern into the current grid at the
+// row/col offset given by query parameters (default 0,0).
+func handleStampPattern(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/api/patterns/")
+	pattern, ok := patternCatalog[name]
+	if !ok {
+		http.Error(w, "Unknown pattern: "+name, http.StatusNotFound)
+		return
+	}
+
+	row, col := parseOffsetQuery(r)
+
+	gridMux.Lock()
+	defer gridMux.Unlock()
+
+	stampPatternLocked(pattern, row, col)
+	recordAndBroadcastLocked(hub)
+
+	w.Header().Set("Content-Type", "application/json")
+	if currentMode == modeInfinite {
+		json.NewEncoder(w).Encode(sparseSnapshotFromRequest(r))
+		return
+	}
+	json.NewEncoder(w).Encode(currentGrid)
+}
+
+// parseOffsetQuery reads the "row" and "col" query parameters, defaulting
+// to 0 when absent or unparsable.
+func parseOffsetQuery(r *http.Request) (row, col int64) {
+	row, _ = strconv.ParseInt(r.URL.Query().Get("row"), 10, 64)
+	col, _ = strconv.ParseInt(r.URL.Query().Get("col"), 10, 64)
+	return row, col
+}
+
+// stampPatternLocked writes a pattern's live cells into the active grid
+// representation at the given offset. Callers must hold gridMux.
+func stampPatternLocked(p Pattern, originRow, originCol int64) {
+	for _, c := range p.Cells {
+		x := originCol + c.X
+		y := originRow + c.Y
+		if currentMode == modeInfinite {
+			currentSparseGrid.SetCell(x, y, true)
+		} else {
+			currentGrid.SetCell(int(y), int(x), true)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// Publisher fans a pre-encoded message out to every subscriber without
+// re-marshalling per recipient, keeping the cost of a broadcast O(1) in the
+// number of viewers rather than O(viewers) in JSON encoding work.
+type Publisher struct {
+	mu          sync.Mutex
+	subscribers map[chan<- []byte]struct{}
+	dropped     uint64
+}
+
+// NewPublisher creates a Publisher with no subscribers.
+func NewPublisher() *Publisher {
+	return &Publisher{subscribers: make(map[chan<- []byte]struct{})}
+}
+
+// SubscribeJSON registers ch to receive every message passed to Publish
+// until done is closed, at which point it is automatically unsubscribed.
+func (p *Publisher) SubscribeJSON(ch chan<- []byte, done <-chan struct{}) {
+	p.mu.Lock()
+	p.subscribers[ch] = struct{}{}
+	p.mu.Unlock()
+
+	go func() {
+		<-done
+		p.mu.Lock()
+		delete(p.subscribers, ch)
+		p.mu.Unlock()
+	}()
+}
+
+// Publish hands message to every current subscriber. Sends are non-blocking:
+// a subscriber whose channel is full is skipped and counted rather than
+// stalling delivery to everyone else.
+func (p *Publisher) Publish(message []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for ch := range p.subscribers {
+		select {
+		case ch <- message:
+		default:
+			dropped := atomic.AddUint64(&p.dropped, 1)
+			log.Printf("Publisher: dropped message for a slow subscriber (total dropped: %d)", dropped)
+		}
+	}
+}
+
+// Dropped returns the running count of messages dropped because a
+// subscriber's channel was full.
+func (p *Publisher) Dropped() uint64 {
+	return atomic.LoadUint64(&p.dropped)
+}
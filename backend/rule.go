@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Rule is a cellular automaton rule in B/S (birth/survival) notation,
+// stored as two bitmasks indexed by neighbor count: bit n of Birth is set
+// if a dead cell with n live neighbors is born, and bit n of Survive is set
+// if a live cell with n live neighbors survives.
+type Rule struct {
+	Birth   uint16
+	Survive uint16
+}
+
+// defaultRule is Conway's original B3/S23.
+var defaultRule = mustParseRule("B3/S23")
+
+// ParseRule parses a Golly-style rulestring such as "B3/S23" (Conway's
+// Life), "B36/S23" (HighLife), "B2/S" (Seeds) or "B3/S12345" (Maze). Digits
+// must be 0-8; anything else is rejected.
+func ParseRule(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "B") || !strings.HasPrefix(parts[1], "S") {
+		return Rule{}, fmt.Errorf("rule: expected form B.../S..., got %q", s)
+	}
+
+	birth, err := parseNeighborDigits(parts[0][1:])
+	if err != nil {
+		return Rule{}, err
+	}
+	survive, err := parseNeighborDigits(parts[1][1:])
+	if err != nil {
+		return Rule{}, err
+	}
+	return Rule{Birth: birth, Survive: survive}, nil
+}
+
+func mustParseRule(s string) Rule {
+	r, err := ParseRule(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// parseNeighborDigits turns a string of digits 0-8 into a bitmask with one
+// bit set per digit present.
+func parseNeighborDigits(digits string) (uint16, error) {
+	var mask uint16
+	for _, ch := range digits {
+		if ch < '0' || ch > '8' {
+			return 0, fmt.Errorf("rule: invalid neighbor count %q (must be 0-8)", ch)
+		}
+		mask |= 1 << uint(ch-'0')
+	}
+	return mask, nil
+}
+
+// String renders the rule back into Golly-style B/S notation.
+func (r Rule) String() string {
+	return fmt.Sprintf("B%s/S%s", neighborDigits(r.Birth), neighborDigits(r.Survive))
+}
+
+func neighborDigits(mask uint16) string {
+	var b strings.Builder
+	for n := 0; n <= 8; n++ {
+		if mask&(1<<uint(n)) != 0 {
+			b.WriteByte(byte('0' + n))
+		}
+	}
+	return b.String()
+}
+
+// MarshalJSON encodes a Rule as its rulestring, e.g. "B3/S23".
+func (r Rule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON decodes a Rule from its rulestring.
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseRule(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// setRuleRequest is the body of POST /api/grid/rule.
+type setRuleRequest struct {
+	Rule string `json:"rule"`
+}
+
+// activeRuleLocked returns the rule of whichever grid representation is
+// currently active. Callers must hold gridMux.
+func activeRuleLocked() Rule {
+	if currentMode == modeInfinite {
+		return currentSparseGrid.Rule
+	}
+	return currentGrid.Rule
+}
+
+// handleSetRule parses a rulestring from the request body and applies it to
+// the active grid representation.
+func handleSetRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req setRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule, err := ParseRule(req.Rule)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	gridMux.Lock()
+	defer gridMux.Unlock()
+
+	if currentMode == modeInfinite {
+		currentSparseGrid.Rule = rule
+	} else {
+		currentGrid.Rule = rule
+	}
+	recordAndBroadcastLocked(hub)
+
+	w.Header().Set("Content-Type", "application/json")
+	if currentMode == modeInfinite {
+		json.NewEncoder(w).Encode(sparseSnapshotFromRequest(r))
+		return
+	}
+	json.NewEncoder(w).Encode(currentGrid)
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestParseRuleRoundTrip(t *testing.T) {
+	cases := []string{"B3/S23", "B36/S23", "B2/S", "B3/S012345678"}
+	for _, s := range cases {
+		rule, err := ParseRule(s)
+		if err != nil {
+			t.Errorf("ParseRule(%q) returned error: %v", s, err)
+			continue
+		}
+		if got := rule.String(); got != s {
+			t.Errorf("ParseRule(%q).String() = %q, want %q", s, got, s)
+		}
+	}
+}
+
+func TestParseRuleMasks(t *testing.T) {
+	rule, err := ParseRule("B3/S23")
+	if err != nil {
+		t.Fatalf("ParseRule(\"B3/S23\") returned error: %v", err)
+	}
+	if rule.Birth != 1<<3 {
+		t.Errorf("Birth = %b, want %b", rule.Birth, 1<<3)
+	}
+	if rule.Survive != 1<<2|1<<3 {
+		t.Errorf("Survive = %b, want %b", rule.Survive, 1<<2|1<<3)
+	}
+}
+
+func TestParseRuleRejectsInvalidDigit(t *testing.T) {
+	if _, err := ParseRule("B9/S23"); err == nil {
+		t.Error("ParseRule(\"B9/S23\") returned nil error, want error for out-of-range digit 9")
+	}
+}
+
+func TestParseRuleRejectsMalformedInput(t *testing.T) {
+	cases := []string{"B3S23", "X3/S23", "B3/X23", "", "B3/"}
+	for _, s := range cases {
+		if _, err := ParseRule(s); err == nil {
+			t.Errorf("ParseRule(%q) returned nil error, want error", s)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import "sync"
+
+// Cell identifies a single coordinate in an unbounded grid.
+type Cell struct {
+	X int64 `json:"x"`
+	Y int64 `json:"y"`
+}
+
+// SparseGrid represents a Game of Life world as the set of its live cells,
+// rather than a dense array. Unlike Grid it has no fixed dimensions, so the
+// simulated world can grow arbitrarily large as long as it stays mostly empty.
+type SparseGrid struct {
+	Generation int64
+	Live       map[Cell]struct{}
+	Rule       Rule
+	epoch      int64 // bumped whenever the grid is replaced or cleared; see nextEpoch
+	mu         sync.Mutex
+}
+
+// NewSparseGrid creates an empty infinite grid using Conway's B3/S23 rule.
+func NewSparseGrid() *SparseGrid {
+	nextEpoch++
+	return &SparseGrid{
+		Live:  make(map[Cell]struct{}),
+		Rule:  defaultRule,
+		epoch: nextEpoch,
+	}
+}
+
+// SetCell sets whether the cell at (x, y) is alive.
+func (g *SparseGrid) SetCell(x, y int64, state bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	cell := Cell{X: x, Y: y}
+	if state {
+		g.Live[cell] = struct{}{}
+	} else {
+		delete(g.Live, cell)
+	}
+}
+
+// ClearAllCells removes every live cell and resets the generation counter.
+func (g *SparseGrid) ClearAllCells() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.Live = make(map[Cell]struct{})
+	g.Generation = 0
+	nextEpoch++
+	g.epoch = nextEpoch
+}
+
+// neighbors returns the eight coordinates surrounding a cell.
+func neighbors(c Cell) [8]Cell {
+	return [8]Cell{
+		{c.X - 1, c.Y - 1}, {c.X, c.Y - 1}, {c.X + 1, c.Y - 1},
+		{c.X - 1, c.Y}, {c.X + 1, c.Y},
+		{c.X - 1, c.Y + 1}, {c.X, c.Y + 1}, {c.X + 1, c.Y + 1},
+	}
+}
+
+// NextGeneration advances the world by one step using g.Rule. Only live
+// cells and their neighbors are examined, so cost scales with the
+// population rather than with the size of the world.
+func (g *SparseGrid) NextGeneration() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	candidates := make(map[Cell]int, len(g.Live)*4)
+	for cell := range g.Live {
+		// Seed every live cell so a rule whose Survive mask includes 0
+		// neighbors (unusual, but valid B/S notation) is still evaluated.
+		if _, ok := candidates[cell]; !ok {
+			candidates[cell] = 0
+		}
+		for _, n := range neighbors(cell) {
+			candidates[n]++
+		}
+	}
+
+	next := make(map[Cell]struct{}, len(g.Live))
+	for cell, liveNeighbors := range candidates {
+		_, alive := g.Live[cell]
+		mask := g.Rule.Birth
+		if alive {
+			mask = g.Rule.Survive
+		}
+		if mask>>uint(liveNeighbors)&1 == 1 {
+			next[cell] = struct{}{}
+		}
+	}
+
+	g.Live = next
+	g.Generation++
+}
+
+// CellsIn returns the live cells inside the inclusive rectangle
+// [minX, maxX] x [minY, maxY], for clients that only want a viewport.
+func (g *SparseGrid) CellsIn(minX, minY, maxX, maxY int64) []Cell {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	cells := make([]Cell, 0, len(g.Live))
+	for cell := range g.Live {
+		if cell.X >= minX && cell.X <= maxX && cell.Y >= minY && cell.Y <= maxY {
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+// Cells returns every live cell in the world.
+func (g *SparseGrid) Cells() []Cell {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	cells := make([]Cell, 0, len(g.Live))
+	for cell := range g.Live {
+		cells = append(cells, cell)
+	}
+	return cells
+}